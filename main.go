@@ -1,242 +1,449 @@
 package main
 
 import (
-	"encoding/binary"
+	"bytes"
+	"flag"
+	"fmt"
 	"io"
 	"log"
 	"math"
 	"os"
 	"strconv"
+	"strings"
+
+	"ambiencefix/wave"
 )
 
-type WAVHeader struct {
-	ChunkID   [4]byte
-	ChunkSize int32
-	Format    [4]byte
-}
+var loopFlag = flag.String("loop", "", "start[:end] in seconds for a sustain loop, written as an smpl chunk (end defaults to the end of the data chunk)")
+var generateFlag = flag.String("generate", "", "silence:Ns or sine:Ns@freq — synthesize the input instead of reading a file")
+var rateFlag = flag.Int("rate", 44100, "sample rate in Hz, for -generate")
+var channelsFlag = flag.Int("channels", 1, "channel count, for -generate")
+var bitsFlag = flag.Int("bits", 16, "bits per sample, for -generate")
 
-type FmtChunk struct {
-	ChunkID       [4]byte
-	ChunkSize     int32
-	AudioFormat   int16
-	NumChannels   int16
-	SampleRate    int32
-	ByteRate      int32
-	BlockAlign    int16
-	BitsPerSample int16
+func checkErr(err error, msg string) {
+	if err != nil {
+		log.Fatalf("%s: %v", msg, err)
+	}
 }
 
-type ChunkHeader struct {
-	ID   [4]byte
-	Size int32
+// cueArg is one `offset[:label]` argument from the command line.
+type cueArg struct {
+	offsetSeconds float64
+	label         string
 }
 
-type CuePoint struct {
-	Identifier   int32
-	Position     uint32
-	ChunkID      [4]byte
-	ChunkStart   int32
-	BlockStart   int32
-	SampleOffset uint32
-}
+func parseCueArgs(args []string) ([]cueArg, error) {
+	cues := make([]cueArg, len(args))
+	for i, arg := range args {
+		parts := strings.SplitN(arg, ":", 2)
 
-type CueChunk struct {
-	ChunkID     [4]byte
-	ChunkSize   int32
-	DwCuePoints int32
-	CuePoints   [1]CuePoint
-}
+		offsetSeconds, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse offset %q: %w", parts[0], err)
+		}
 
-type ListChunk struct {
-	ChunkID   [4]byte
-	ChunkSize int32
-	FormType  [4]byte
-}
+		label := fmt.Sprintf("MARK%03d", i+1)
+		if len(parts) == 2 && parts[1] != "" {
+			label = parts[1]
+		}
 
-type LtxtChunk struct {
-	ChunkID        [4]byte
-	ChunkSize      int32
-	DwName         uint32
-	DwSampleLength uint32
-	DwPurpose      [4]byte
-	DwCountry      int16
-	DwLanguage     int16
-	DwDialect      int16
-	DwCodePage     int16
+		cues[i] = cueArg{offsetSeconds: offsetSeconds, label: label}
+	}
+	return cues, nil
 }
 
-type LablChunk struct {
-	ChunkID   [4]byte
-	ChunkSize int32
-	CueID     int32
-	LabelText [8]byte
+func cuePosition(offsetSeconds float64, sampleRate int32) uint32 {
+	return uint32(math.Floor(offsetSeconds * float64(sampleRate)))
 }
 
-type NoteChunk struct {
-	ChunkID   [4]byte
-	ChunkSize int32
-	CueID     int32
-	NoteText  [6]byte
+// loopArg is a parsed `-loop start[:end]` flag value.
+type loopArg struct {
+	startSeconds float64
+	endSeconds   float64
+	hasEnd       bool
 }
 
-func findChunk(r *io.SectionReader, chunkID string) (io.ReadSeeker, int32, error) {
-	var header ChunkHeader
-	var position int64 = 0
+func parseLoopArg(arg string) (loopArg, error) {
+	parts := strings.SplitN(arg, ":", 2)
 
-	_, err := r.Seek(12, io.SeekStart) // skip RIFF header
+	start, err := strconv.ParseFloat(parts[0], 64)
 	if err != nil {
-		return nil, 0, err
+		return loopArg{}, fmt.Errorf("could not parse loop start %q: %w", parts[0], err)
 	}
-	position = 12
 
-	for {
-		err = binary.Read(r, binary.LittleEndian, &header)
+	loop := loopArg{startSeconds: start}
+	if len(parts) == 2 {
+		end, err := strconv.ParseFloat(parts[1], 64)
 		if err != nil {
-			return nil, 0, err
+			return loopArg{}, fmt.Errorf("could not parse loop end %q: %w", parts[1], err)
 		}
+		loop.endSeconds = end
+		loop.hasEnd = true
+	}
+	return loop, nil
+}
 
-		if string(header.ID[:]) == chunkID {
-			length := header.Size + int32(binary.Size(header)) + 1
-			return io.NewSectionReader(r, position, int64(length)), length, nil
-		}
+// buildSamplerChunk builds the smpl chunk for a sustain loop spanning
+// loop.startSeconds to loop.endSeconds (or the end of the data chunk, if
+// no end was given).
+func buildSamplerChunk(loop loopArg, fmtChunk wave.FmtChunk, dataSize int32) wave.SamplerChunk {
+	start := cuePosition(loop.startSeconds, fmtChunk.SampleRate)
 
-		_, err = r.Seek(int64(header.Size), io.SeekCurrent)
-		if err != nil {
-			return nil, 0, err
-		}
+	end := cuePosition(loop.endSeconds, fmtChunk.SampleRate)
+	if !loop.hasEnd {
+		totalFrames := uint32(dataSize) / uint32(fmtChunk.BlockAlign)
+		end = totalFrames - 1
+	}
 
-		position += int64(header.Size) + int64(binary.Size(header))
+	return wave.SamplerChunk{
+		SamplePeriod:  uint32(1e9 / int64(fmtChunk.SampleRate)),
+		MIDIUnityNote: 60,
+		Loops: []wave.SampleLoop{{
+			Start: start,
+			End:   end,
+		}},
 	}
 }
 
-func checkErr(err error, msg string) {
-	if err != nil {
-		log.Fatalf("%s: %v", msg, err)
-	}
+// generateArg is a parsed `-generate` flag value: either
+// "silence:<duration>s" or "sine:<duration>s@<freq>".
+type generateArg struct {
+	kind            string
+	durationSeconds float64
+	freqHz          float64
 }
 
-func main() {
-	if len(os.Args) < 4 {
-		log.Fatalf("Usage: %s <offset-seconds> <input.wav> <output.wav>\n", os.Args[0])
+func parseGenerateArg(arg string) (generateArg, error) {
+	kind, rest, ok := strings.Cut(arg, ":")
+	if !ok {
+		return generateArg{}, fmt.Errorf("could not parse -generate %q: expected kind:duration", arg)
 	}
 
-	offsetSeconds, err := strconv.ParseFloat(os.Args[1], 64)
-	checkErr(err, "Could not parse timestamp")
-
-	inputFilename := os.Args[2]
-	outputFilename := os.Args[3]
-
-	input, err := os.Open(inputFilename)
-	checkErr(err, "Could not open input file")
-	defer input.Close()
-
-	header := WAVHeader{}
-	err = binary.Read(input, binary.LittleEndian, &header)
-	checkErr(err, "Could not read WAV header")
+	durationStr := rest
+	var freqHz float64
+	if kind == "sine" {
+		var freqStr string
+		durationStr, freqStr, ok = strings.Cut(rest, "@")
+		if !ok {
+			return generateArg{}, fmt.Errorf("could not parse -generate %q: sine requires duration@freq", arg)
+		}
+		var err error
+		freqHz, err = strconv.ParseFloat(freqStr, 64)
+		if err != nil {
+			return generateArg{}, fmt.Errorf("could not parse -generate frequency %q: %w", freqStr, err)
+		}
+	} else if kind != "silence" {
+		return generateArg{}, fmt.Errorf("could not parse -generate %q: unknown kind %q", arg, kind)
+	}
 
-	fileInfo, err := input.Stat()
-	checkErr(err, "Could not stat file")
+	durationSeconds, err := strconv.ParseFloat(strings.TrimSuffix(durationStr, "s"), 64)
+	if err != nil {
+		return generateArg{}, fmt.Errorf("could not parse -generate duration %q: %w", durationStr, err)
+	}
+	if durationSeconds <= 0 {
+		return generateArg{}, fmt.Errorf("could not parse -generate %q: duration must be positive", arg)
+	}
 
-	r := io.NewSectionReader(input, 0, fileInfo.Size())
+	return generateArg{kind: kind, durationSeconds: durationSeconds, freqHz: freqHz}, nil
+}
 
-	fmtReader, _, err := findChunk(r, "fmt ")
-	checkErr(err, "Could not find fmt chunk")
+// validateGenerateFlags checks the -rate/-channels/-bits flags used by
+// -generate. -bits in particular must be one of the depths putSample
+// understands: other values either write samples of a different width than
+// the fmt chunk declares, or (e.g. -bits 7 with one channel) truncate
+// NewPCMFmtChunk's BlockAlign to 0, which later divides by zero when
+// computing a default loop end.
+func validateGenerateFlags() error {
+	if *rateFlag <= 0 || *channelsFlag <= 0 {
+		return fmt.Errorf("-rate and -channels must be positive")
+	}
+	switch *bitsFlag {
+	case 8, 16, 24, 32:
+	default:
+		return fmt.Errorf("-bits must be one of 8, 16, 24, or 32, got %d", *bitsFlag)
+	}
+	return nil
+}
 
-	var fmtChunk FmtChunk
-	err = binary.Read(fmtReader, binary.LittleEndian, &fmtChunk)
-	checkErr(err, "Could not read fmt chunk")
+// generate synthesizes a PCM bed matching g, using the -rate/-channels/-bits
+// flags, and returns its fmt chunk, data size in bytes, and a reader that
+// streams the samples without materializing the whole bed in memory.
+func generate(g generateArg) (wave.FmtChunk, int32, io.Reader) {
+	fmtChunk := wave.NewPCMFmtChunk(*channelsFlag, *rateFlag, *bitsFlag)
+
+	totalFrames := int64(math.Round(g.durationSeconds * float64(fmtChunk.SampleRate)))
+	dataSize := int32(totalFrames) * int32(fmtChunk.BlockAlign)
+
+	var gen wave.FrameGenerator
+	switch g.kind {
+	case "sine":
+		gen = wave.SineGenerator(fmtChunk.SampleRate, g.freqHz, *bitsFlag)
+	default:
+		gen = wave.SilenceGenerator()
+	}
 
-	dataReader, dataChunkSize, err := findChunk(r, "data")
-	checkErr(err, "Could not find data chunk")
+	return fmtChunk, dataSize, wave.NewFrameReader(totalFrames, *channelsFlag, *bitsFlag, gen)
+}
 
-	output, err := os.Create(outputFilename)
-	checkErr(err, "Could not create output file")
-	defer output.Close()
+// rawChunk is a chunk this tool doesn't otherwise understand (e.g. "bext",
+// "iXML", "JUNK"), kept around so it can be copied through to the output
+// verbatim.
+type rawChunk struct {
+	id   string
+	data []byte
+}
 
-	err = binary.Write(output, binary.LittleEndian, &header)
-	checkErr(err, "Could not write WAV header")
+// process reads a WAV from input (or, if gen is non-nil, synthesizes one
+// instead of reading at all), merges in cues and an optional sustain loop,
+// and writes the result to output. It holds main's entire chunk-walk,
+// passthrough, and cue/smpl-merge logic so that logic can be driven
+// directly by tests, independent of flag parsing and file I/O.
+func process(input io.Reader, output io.WriteSeeker, cues []cueArg, loop loopArg, hasLoop bool, gen *generateArg) error {
+	var header wave.Header
+	var fmtChunk wave.FmtChunk
+	var dataSize int32
+	var existingCue wave.CueChunk
+	var existingList wave.AssocDataList
+	var existingSmpl wave.SamplerChunk
+	var hasSmpl bool
+	var passthrough []rawChunk
+
+	if _, err := header.WriteTo(output); err != nil {
+		return fmt.Errorf("could not write placeholder WAV header: %w", err)
+	}
 
-	err = binary.Write(output, binary.LittleEndian, &fmtChunk)
-	checkErr(err, "Could not write fmt chunk")
+	if gen != nil {
+		var dataReader io.Reader
+		fmtChunk, dataSize, dataReader = generate(*gen)
 
-	chunkHeaderSize := int32(binary.Size(ChunkHeader{}))
+		if _, err := fmtChunk.WriteTo(output); err != nil {
+			return fmt.Errorf("could not write fmt chunk: %w", err)
+		}
+		if err := wave.WriteChunkHeader(output, "data", dataSize); err != nil {
+			return fmt.Errorf("could not write data chunk header: %w", err)
+		}
+		if _, err := io.Copy(output, dataReader); err != nil {
+			return fmt.Errorf("could not write data chunk: %w", err)
+		}
+		if err := wave.WritePad(output, dataSize); err != nil {
+			return fmt.Errorf("could not pad data chunk: %w", err)
+		}
+	} else {
+		if _, err := wave.ReadHeader(input); err != nil {
+			return fmt.Errorf("could not read WAV header: %w", err)
+		}
 
-	cuePosition := uint32(math.Floor(offsetSeconds * float64(fmtChunk.SampleRate) * float64(fmtChunk.NumChannels)))
-	dataSize := dataChunkSize - chunkHeaderSize
+		dataWritten := false
+		chunks := wave.NewChunkReader(input)
+		for {
+			id, size, section, err := chunks.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("could not read chunk: %w", err)
+			}
+
+			switch id {
+			case "fmt ":
+				fmtChunk, err = wave.DecodeFmtChunk(section, size)
+				if err != nil {
+					return fmt.Errorf("could not decode fmt chunk: %w", err)
+				}
+				if _, err := fmtChunk.WriteTo(output); err != nil {
+					return fmt.Errorf("could not write fmt chunk: %w", err)
+				}
+			case "data":
+				dataSize = size
+
+				if err := wave.WriteChunkHeader(output, "data", dataSize); err != nil {
+					return fmt.Errorf("could not write data chunk header: %w", err)
+				}
+				if _, err := io.Copy(output, section); err != nil {
+					return fmt.Errorf("could not write data chunk: %w", err)
+				}
+				if err := wave.WritePad(output, dataSize); err != nil {
+					return fmt.Errorf("could not pad data chunk: %w", err)
+				}
+
+				dataWritten = true
+			case "cue ":
+				existingCue, err = wave.DecodeCueChunk(section)
+				if err != nil {
+					return fmt.Errorf("could not decode existing cue chunk: %w", err)
+				}
+			case "smpl":
+				existingSmpl, err = wave.DecodeSamplerChunk(section)
+				if err != nil {
+					return fmt.Errorf("could not decode existing smpl chunk: %w", err)
+				}
+				hasSmpl = true
+			case "LIST":
+				data, err := io.ReadAll(section)
+				if err != nil {
+					return fmt.Errorf("could not read LIST chunk: %w", err)
+				}
+
+				if len(data) >= 4 && string(data[:4]) == "adtl" {
+					existingList, err = wave.DecodeAssocDataList(bytes.NewReader(data), int64(len(data)))
+					if err != nil {
+						return fmt.Errorf("could not decode existing adtl list: %w", err)
+					}
+				} else {
+					passthrough = append(passthrough, rawChunk{id: id, data: data})
+				}
+			default:
+				data, err := io.ReadAll(section)
+				if err != nil {
+					return fmt.Errorf("could not read %s chunk: %w", id, err)
+				}
+				passthrough = append(passthrough, rawChunk{id: id, data: data})
+			}
+		}
+		if !dataWritten {
+			return fmt.Errorf("could not find data chunk")
+		}
+	}
 
-	_, err = io.CopyN(output, dataReader, int64(dataChunkSize))
-	checkErr(err, "Could not write data chunk")
+	for _, chunk := range passthrough {
+		if err := wave.WriteChunkHeader(output, chunk.id, int32(len(chunk.data))); err != nil {
+			return fmt.Errorf("could not write %s chunk header: %w", chunk.id, err)
+		}
+		if _, err := output.Write(chunk.data); err != nil {
+			return fmt.Errorf("could not write %s chunk: %w", chunk.id, err)
+		}
+		if err := wave.WritePad(output, int32(len(chunk.data))); err != nil {
+			return fmt.Errorf("could not pad %s chunk: %w", chunk.id, err)
+		}
+	}
 
-	cuePoint := CuePoint{
-		Identifier:   1,
-		Position:     cuePosition,
-		ChunkID:      [4]byte{'d', 'a', 't', 'a'},
-		ChunkStart:   0,
-		BlockStart:   0,
-		SampleOffset: cuePosition,
+	// New cue points are numbered after the highest identifier already in
+	// use, so merging never collides with cues the input file came with.
+	nextCueID := int32(1)
+	for _, point := range existingCue.CuePoints {
+		if point.Identifier >= nextCueID {
+			nextCueID = point.Identifier + 1
+		}
 	}
 
-	cue := CueChunk{
-		ChunkID:     [4]byte{'c', 'u', 'e', ' '},
-		ChunkSize:   int32(binary.Size(CueChunk{})) - chunkHeaderSize,
-		DwCuePoints: 1,
-		CuePoints:   [1]CuePoint{cuePoint},
+	cuePoints := append([]wave.CuePoint{}, existingCue.CuePoints...)
+	list := existingList
+	for i, c := range cues {
+		id := nextCueID + int32(i)
+		pos := cuePosition(c.offsetSeconds, fmtChunk.SampleRate)
+
+		cuePoints = append(cuePoints, wave.CuePoint{
+			Identifier:   id,
+			Position:     pos,
+			ChunkID:      [4]byte{'d', 'a', 't', 'a'},
+			SampleOffset: pos,
+		})
+
+		list.Labels = append(list.Labels, wave.Label{CueID: id, Text: c.label})
+		list.Notes = append(list.Notes, wave.Note{CueID: id, Text: "Range"})
+		list.LabeledTexts = append(list.LabeledTexts, wave.LabeledText{
+			CueID:        id,
+			SampleLength: uint32(dataSize)/uint32(fmtChunk.BlockAlign) - pos,
+			Purpose:      [4]byte{'m', 'a', 'r', 'k'},
+			Country:      1,
+		})
 	}
 
-	err = binary.Write(output, binary.LittleEndian, &cue)
-	checkErr(err, "Could not write Cue chunk")
+	cueChunk := wave.CueChunk{CuePoints: cuePoints}
+	if _, err := cueChunk.WriteTo(output); err != nil {
+		return fmt.Errorf("could not write cue chunk: %w", err)
+	}
+	if _, err := list.WriteTo(output); err != nil {
+		return fmt.Errorf("could not write list chunk: %w", err)
+	}
 
-	note := NoteChunk{
-		ChunkID:   [4]byte{'n', 'o', 't', 'e'},
-		ChunkSize: int32(binary.Size(NoteChunk{})) - chunkHeaderSize,
-		CueID:     1,
-		NoteText:  [6]byte{'R', 'a', 'n', 'g', 'e', 0},
+	// A -loop flag replaces any smpl chunk the input already had, rather
+	// than appending a second one: a WAV file carries at most one.
+	switch {
+	case hasLoop:
+		sampler := buildSamplerChunk(loop, fmtChunk, dataSize)
+		if _, err := sampler.WriteTo(output); err != nil {
+			return fmt.Errorf("could not write smpl chunk: %w", err)
+		}
+	case hasSmpl:
+		if _, err := existingSmpl.WriteTo(output); err != nil {
+			return fmt.Errorf("could not write existing smpl chunk: %w", err)
+		}
 	}
 
-	labl := LablChunk{
-		ChunkID:   [4]byte{'l', 'a', 'b', 'l'},
-		ChunkSize: int32(binary.Size(LablChunk{})) - chunkHeaderSize,
-		CueID:     1,
-		LabelText: [8]byte{'M', 'A', 'R', 'K', '0', '0', '1', 0},
+	offset, err := output.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("could not check file size: %w", err)
+	}
+	if _, err := output.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("could not seek to start: %w", err)
 	}
 
-	ltxt := LtxtChunk{
-		ChunkID:        [4]byte{'l', 't', 'x', 't'},
-		ChunkSize:      int32(binary.Size(LtxtChunk{})) - chunkHeaderSize,
-		DwName:         cuePosition,
-		DwSampleLength: uint32(dataSize) - cuePosition - 1,
-		DwPurpose:      [4]byte{'m', 'a', 'r', 'k'},
-		DwCountry:      1,
-		DwLanguage:     0,
-		DwDialect:      0,
-		DwCodePage:     0,
+	header.ChunkSize = int32(offset)
+	if _, err := header.WriteTo(output); err != nil {
+		return fmt.Errorf("could not update header: %w", err)
 	}
+	return nil
+}
 
-	listChunk := ListChunk{
-		ChunkID:   [4]byte{'L', 'I', 'S', 'T'},
-		ChunkSize: int32(binary.Size(ListChunk{})) - chunkHeaderSize + int32(binary.Size(ltxt)+binary.Size(labl)+binary.Size(note)),
-		FormType:  [4]byte{'a', 'd', 't', 'l'},
+func main() {
+	flag.Parse()
+	args := flag.Args()
+
+	generating := *generateFlag != ""
+	minArgs := 3
+	if generating {
+		minArgs = 2
+	}
+	if len(args) < minArgs {
+		log.Fatalf("Usage: %s [-loop start[:end]] [-generate kind:Ns[@freq]] <offset[:label]>... [<input.wav>|-] <output.wav>\n", os.Args[0])
 	}
 
-	err = binary.Write(output, binary.LittleEndian, &listChunk)
-	checkErr(err, "Could not write list chunk")
+	var cueArgs []string
+	var inputFilename, outputFilename string
+	if generating {
+		cueArgs = args[:len(args)-1]
+		outputFilename = args[len(args)-1]
+	} else {
+		cueArgs = args[:len(args)-2]
+		inputFilename = args[len(args)-2]
+		outputFilename = args[len(args)-1]
+	}
 
-	err = binary.Write(output, binary.LittleEndian, &ltxt)
-	checkErr(err, "Could not write ltxt chunk")
+	cues, err := parseCueArgs(cueArgs)
+	checkErr(err, "Could not parse cue points")
 
-	err = binary.Write(output, binary.LittleEndian, &labl)
-	checkErr(err, "Could not write labl chunk")
+	hasLoop := *loopFlag != ""
+	var loop loopArg
+	if hasLoop {
+		loop, err = parseLoopArg(*loopFlag)
+		checkErr(err, "Could not parse loop")
+	}
 
-	err = binary.Write(output, binary.LittleEndian, &note)
-	checkErr(err, "Could not write note chunk")
+	var gen *generateArg
+	if generating {
+		g, err := parseGenerateArg(*generateFlag)
+		checkErr(err, "Could not parse -generate")
+		checkErr(validateGenerateFlags(), "Invalid -generate flags")
+		gen = &g
+	}
 
-	offset, err := output.Seek(0, io.SeekCurrent)
-	checkErr(err, "Could not check file size")
+	var input io.Reader
+	if !generating {
+		if inputFilename == "-" {
+			input = os.Stdin
+		} else {
+			f, err := os.Open(inputFilename)
+			checkErr(err, "Could not open input file")
+			defer f.Close()
+			input = f
+		}
+	}
 
-	_, err = output.Seek(0, io.SeekStart)
-	checkErr(err, "Could not seek to start")
+	output, err := os.Create(outputFilename)
+	checkErr(err, "Could not create output file")
+	defer output.Close()
 
-	header.ChunkSize = int32(offset)
-	err = binary.Write(output, binary.LittleEndian, &header)
-	checkErr(err, "Could not update header")
+	err = process(input, output, cues, loop, hasLoop, gen)
+	checkErr(err, "Could not process WAV file")
 }
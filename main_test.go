@@ -0,0 +1,148 @@
+package main
+
+import "testing"
+
+func TestValidateGenerateFlags(t *testing.T) {
+	origRate, origChannels, origBits := *rateFlag, *channelsFlag, *bitsFlag
+	defer func() {
+		*rateFlag, *channelsFlag, *bitsFlag = origRate, origChannels, origBits
+	}()
+
+	tests := []struct {
+		name           string
+		rate, channels int
+		bits           int
+		wantErr        bool
+	}{
+		{name: "defaults", rate: 44100, channels: 1, bits: 16},
+		{name: "8-bit", rate: 44100, channels: 2, bits: 8},
+		{name: "24-bit", rate: 44100, channels: 1, bits: 24},
+		{name: "32-bit", rate: 44100, channels: 1, bits: 32},
+		{name: "unsupported bits", rate: 44100, channels: 1, bits: 7, wantErr: true},
+		{name: "zero rate", rate: 0, channels: 1, bits: 16, wantErr: true},
+		{name: "negative channels", rate: 44100, channels: -1, bits: 16, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			*rateFlag, *channelsFlag, *bitsFlag = tt.rate, tt.channels, tt.bits
+			err := validateGenerateFlags()
+			if tt.wantErr && err == nil {
+				t.Fatalf("validateGenerateFlags() with bits=%d = nil, want error", tt.bits)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("validateGenerateFlags() with bits=%d = %v, want nil", tt.bits, err)
+			}
+		})
+	}
+}
+
+func TestParseCueArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []cueArg
+		wantErr bool
+	}{
+		{
+			name: "labeled and default labels",
+			args: []string{"1.5:Intro", "3"},
+			want: []cueArg{
+				{offsetSeconds: 1.5, label: "Intro"},
+				{offsetSeconds: 3, label: "MARK002"},
+			},
+		},
+		{name: "bad offset", args: []string{"nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCueArgs(tt.args)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseCueArgs(%v) = %+v, want error", tt.args, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCueArgs(%v) returned error: %v", tt.args, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseCueArgs(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseCueArgs(%v)[%d] = %+v, want %+v", tt.args, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseLoopArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    loopArg
+		wantErr bool
+	}{
+		{name: "start only", arg: "0.5", want: loopArg{startSeconds: 0.5}},
+		{name: "start and end", arg: "0.5:2", want: loopArg{startSeconds: 0.5, endSeconds: 2, hasEnd: true}},
+		{name: "bad start", arg: "nope", wantErr: true},
+		{name: "bad end", arg: "0.5:nope", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLoopArg(tt.arg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseLoopArg(%q) = %+v, want error", tt.arg, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLoopArg(%q) returned error: %v", tt.arg, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseLoopArg(%q) = %+v, want %+v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGenerateArg(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    generateArg
+		wantErr bool
+	}{
+		{name: "silence", arg: "silence:2s", want: generateArg{kind: "silence", durationSeconds: 2}},
+		{name: "sine", arg: "sine:1.5s@440", want: generateArg{kind: "sine", durationSeconds: 1.5, freqHz: 440}},
+		{name: "missing colon", arg: "silence2s", wantErr: true},
+		{name: "unknown kind", arg: "noise:2s", wantErr: true},
+		{name: "sine missing freq", arg: "sine:2s", wantErr: true},
+		{name: "bad duration", arg: "silence:abcs", wantErr: true},
+		{name: "zero duration", arg: "silence:0s", wantErr: true},
+		{name: "negative duration", arg: "silence:-1s", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseGenerateArg(tt.arg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseGenerateArg(%q) = %+v, want error", tt.arg, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseGenerateArg(%q) returned error: %v", tt.arg, err)
+			}
+			if got != tt.want {
+				t.Errorf("parseGenerateArg(%q) = %+v, want %+v", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
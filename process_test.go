@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ambiencefix/wave"
+)
+
+// buildFixtureWAV returns a WAV file that already has a cue point, an adtl
+// label for it, and an unrecognized "bext" chunk this tool must pass
+// through verbatim.
+func buildFixtureWAV(t *testing.T) []byte {
+	t.Helper()
+
+	fmtChunk := wave.NewPCMFmtChunk(1, 8000, 16)
+	data := make([]byte, 1600) // 800 frames at 8000Hz, 1ch, 16-bit
+
+	existingCue := wave.CueChunk{CuePoints: []wave.CuePoint{
+		{Identifier: 5, Position: 50, ChunkID: [4]byte{'d', 'a', 't', 'a'}, SampleOffset: 50},
+	}}
+	existingList := wave.AssocDataList{
+		Labels: []wave.Label{{CueID: 5, Text: "Existing"}},
+	}
+
+	var chunks bytes.Buffer
+	if _, err := fmtChunk.WriteTo(&chunks); err != nil {
+		t.Fatalf("WriteTo fmt: %v", err)
+	}
+	if err := wave.WriteChunkHeader(&chunks, "data", int32(len(data))); err != nil {
+		t.Fatalf("WriteChunkHeader data: %v", err)
+	}
+	chunks.Write(data)
+	if err := wave.WritePad(&chunks, int32(len(data))); err != nil {
+		t.Fatalf("WritePad data: %v", err)
+	}
+	if _, err := existingCue.WriteTo(&chunks); err != nil {
+		t.Fatalf("WriteTo cue: %v", err)
+	}
+	if _, err := existingList.WriteTo(&chunks); err != nil {
+		t.Fatalf("WriteTo list: %v", err)
+	}
+	if err := wave.WriteChunkHeader(&chunks, "bext", 4); err != nil {
+		t.Fatalf("WriteChunkHeader bext: %v", err)
+	}
+	chunks.WriteString("DATA")
+
+	header := wave.Header{ChunkSize: int32(4 + chunks.Len())}
+	var full bytes.Buffer
+	if _, err := header.WriteTo(&full); err != nil {
+		t.Fatalf("WriteTo header: %v", err)
+	}
+	full.Write(chunks.Bytes())
+
+	return full.Bytes()
+}
+
+func TestProcessMergesCuesAndPassesThroughUnknownChunks(t *testing.T) {
+	input := buildFixtureWAV(t)
+
+	output, err := os.Create(filepath.Join(t.TempDir(), "out.wav"))
+	if err != nil {
+		t.Fatalf("os.Create: %v", err)
+	}
+	defer output.Close()
+
+	cues := []cueArg{{offsetSeconds: 0.1, label: "New"}}
+	if err := process(bytes.NewReader(input), output, cues, loopArg{}, false, nil); err != nil {
+		t.Fatalf("process: %v", err)
+	}
+
+	if _, err := output.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("Seek: %v", err)
+	}
+
+	if _, err := wave.ReadHeader(output); err != nil {
+		t.Fatalf("ReadHeader: %v", err)
+	}
+
+	var gotCue wave.CueChunk
+	var gotList wave.AssocDataList
+	var gotBext []byte
+
+	chunks := wave.NewChunkReader(output)
+	for {
+		id, size, section, err := chunks.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("chunks.Next: %v", err)
+		}
+
+		switch id {
+		case "cue ":
+			gotCue, err = wave.DecodeCueChunk(section)
+			if err != nil {
+				t.Fatalf("DecodeCueChunk: %v", err)
+			}
+		case "LIST":
+			data, err := io.ReadAll(section)
+			if err != nil {
+				t.Fatalf("read LIST body: %v", err)
+			}
+			if len(data) >= 4 && string(data[:4]) == "adtl" {
+				gotList, err = wave.DecodeAssocDataList(bytes.NewReader(data), int64(len(data)))
+				if err != nil {
+					t.Fatalf("DecodeAssocDataList: %v", err)
+				}
+			}
+		case "bext":
+			gotBext, err = io.ReadAll(section)
+			if err != nil {
+				t.Fatalf("read bext: %v", err)
+			}
+			_ = size
+		}
+	}
+
+	wantIDs := []int32{5, 6}
+	if len(gotCue.CuePoints) != len(wantIDs) {
+		t.Fatalf("got %d cue points, want %d: %+v", len(gotCue.CuePoints), len(wantIDs), gotCue.CuePoints)
+	}
+	for i, id := range wantIDs {
+		if gotCue.CuePoints[i].Identifier != id {
+			t.Errorf("cue point %d identifier = %d, want %d", i, gotCue.CuePoints[i].Identifier, id)
+		}
+	}
+
+	wantLabels := map[int32]string{5: "Existing", 6: "New"}
+	if len(gotList.Labels) != len(wantLabels) {
+		t.Fatalf("got %d labels, want %d: %+v", len(gotList.Labels), len(wantLabels), gotList.Labels)
+	}
+	for _, l := range gotList.Labels {
+		if want, ok := wantLabels[l.CueID]; !ok || want != l.Text {
+			t.Errorf("label for cue %d = %q, want %q", l.CueID, l.Text, wantLabels[l.CueID])
+		}
+	}
+
+	if string(gotBext) != "DATA" {
+		t.Errorf("bext passthrough = %q, want %q", gotBext, "DATA")
+	}
+}
@@ -0,0 +1,77 @@
+package wave
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// ChunkHeader is the 8-byte ID+size header that precedes every RIFF chunk.
+type ChunkHeader struct {
+	ID   [4]byte
+	Size int32
+}
+
+// ChunkReader walks the top-level chunks of a RIFF container in a single
+// forward pass, making it usable on any io.Reader — including stdin — not
+// just a seekable file. It makes no assumption about which chunks are
+// present or in what order.
+type ChunkReader struct {
+	r       io.Reader
+	current io.Reader // the in-progress chunk's reader, or nil
+	pad     bool      // whether current has a trailing RIFF pad byte to skip
+}
+
+// NewChunkReader returns a ChunkReader over the chunk sequence read from r,
+// typically starting just past the 12-byte RIFF/WAVE header.
+func NewChunkReader(r io.Reader) *ChunkReader {
+	return &ChunkReader{r: r}
+}
+
+// Next discards any bytes (and pad byte) left unread from the previous
+// chunk, then returns the ID, declared size, and a reader limited to the
+// data of the next top-level chunk. It returns io.EOF once the chunk
+// sequence is exhausted.
+func (c *ChunkReader) Next() (string, int32, io.Reader, error) {
+	if c.current != nil {
+		if _, err := io.Copy(io.Discard, c.current); err != nil {
+			return "", 0, nil, err
+		}
+		if c.pad {
+			if _, err := io.CopyN(io.Discard, c.r, 1); err != nil {
+				return "", 0, nil, err
+			}
+		}
+	}
+
+	var header ChunkHeader
+	if err := binary.Read(c.r, binary.LittleEndian, &header); err != nil {
+		return "", 0, nil, err
+	}
+
+	c.current = io.LimitReader(c.r, int64(header.Size))
+	c.pad = header.Size%2 != 0
+
+	return string(header.ID[:]), header.Size, c.current, nil
+}
+
+// WriteChunkHeader writes a raw 8-byte chunk ID+size header to w, for
+// chunks (such as "data", or any chunk passed through verbatim) that don't
+// have a dedicated Go type.
+func WriteChunkHeader(w io.Writer, id string, size int32) error {
+	var chunkID [4]byte
+	copy(chunkID[:], id)
+	if err := binary.Write(w, binary.LittleEndian, chunkID); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, size)
+}
+
+// WritePad writes the single zero byte RIFF requires after a chunk whose
+// declared size is odd, so the next chunk starts on a WORD boundary.
+func WritePad(w io.Writer, size int32) error {
+	if size%2 == 0 {
+		return nil
+	}
+	_, err := w.Write([]byte{0})
+	return err
+}
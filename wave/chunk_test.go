@@ -0,0 +1,48 @@
+package wave
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestChunkReaderWalksForward(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteChunkHeader(&buf, "fmt ", 4); err != nil {
+		t.Fatalf("WriteChunkHeader: %v", err)
+	}
+	buf.WriteString("abcd")
+	if err := WriteChunkHeader(&buf, "note", 3); err != nil { // odd size: exercises the pad byte
+		t.Fatalf("WriteChunkHeader: %v", err)
+	}
+	buf.WriteString("xyz")
+	if err := WritePad(&buf, 3); err != nil {
+		t.Fatalf("WritePad: %v", err)
+	}
+
+	chunks := NewChunkReader(&buf)
+
+	id, size, section, err := chunks.Next()
+	if err != nil || id != "fmt " || size != 4 {
+		t.Fatalf("first chunk = %q, %d, err=%v; want \"fmt \", 4, nil", id, size, err)
+	}
+	body, err := io.ReadAll(section)
+	if err != nil || string(body) != "abcd" {
+		t.Fatalf("first chunk body = %q, err=%v; want \"abcd\"", body, err)
+	}
+
+	// The odd-sized second chunk's pad byte is skipped by the final Next()
+	// call below, which must then report io.EOF.
+	id, size, section, err = chunks.Next()
+	if err != nil || id != "note" || size != 3 {
+		t.Fatalf("second chunk = %q, %d, err=%v; want \"note\", 3, nil", id, size, err)
+	}
+	body, err = io.ReadAll(section)
+	if err != nil || string(body) != "xyz" {
+		t.Fatalf("second chunk body = %q, err=%v; want \"xyz\"", body, err)
+	}
+
+	if _, _, _, err := chunks.Next(); err != io.EOF {
+		t.Fatalf("Next() at end = %v, want io.EOF", err)
+	}
+}
@@ -0,0 +1,268 @@
+package wave
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// CuePoint marks a single sample position in the "data" chunk.
+type CuePoint struct {
+	Identifier   int32
+	Position     uint32
+	ChunkID      [4]byte
+	ChunkStart   int32
+	BlockStart   int32
+	SampleOffset uint32
+}
+
+// CueChunk is the RIFF "cue " chunk: an ordered list of CuePoints.
+type CueChunk struct {
+	CuePoints []CuePoint
+}
+
+// DecodeCueChunk reads a CueChunk body, as yielded by a ChunkReader for a
+// "cue " chunk.
+func DecodeCueChunk(r io.Reader) (CueChunk, error) {
+	var count int32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return CueChunk{}, err
+	}
+
+	points := make([]CuePoint, count)
+	for i := range points {
+		if err := binary.Read(r, binary.LittleEndian, &points[i]); err != nil {
+			return CueChunk{}, err
+		}
+	}
+	return CueChunk{CuePoints: points}, nil
+}
+
+// WriteTo writes the full "cue " chunk, including its ID and size header,
+// to w.
+func (c CueChunk) WriteTo(w io.Writer) (int64, error) {
+	size := int32(4 + len(c.CuePoints)*binary.Size(CuePoint{}))
+
+	if err := binary.Write(w, binary.LittleEndian, [4]byte{'c', 'u', 'e', ' '}); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, size); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(len(c.CuePoints))); err != nil {
+		return 0, err
+	}
+	for _, point := range c.CuePoints {
+		if err := binary.Write(w, binary.LittleEndian, point); err != nil {
+			return 0, err
+		}
+	}
+	return int64(8 + size), nil
+}
+
+// Label names a cue point, as stored in an adtl "labl" sub-chunk.
+type Label struct {
+	CueID int32
+	Text  string
+}
+
+func (l Label) Encode(w io.Writer) (int64, error) {
+	return encodeText(w, [4]byte{'l', 'a', 'b', 'l'}, l.CueID, l.Text)
+}
+
+// DecodeLabel reads a Label body, as yielded by a ChunkReader for a "labl"
+// sub-chunk.
+func DecodeLabel(r io.Reader) (Label, error) {
+	cueID, text, err := decodeText(r)
+	return Label{CueID: cueID, Text: text}, err
+}
+
+// Note annotates a cue point, as stored in an adtl "note" sub-chunk.
+type Note struct {
+	CueID int32
+	Text  string
+}
+
+func (n Note) Encode(w io.Writer) (int64, error) {
+	return encodeText(w, [4]byte{'n', 'o', 't', 'e'}, n.CueID, n.Text)
+}
+
+// DecodeNote reads a Note body, as yielded by a ChunkReader for a "note"
+// sub-chunk.
+func DecodeNote(r io.Reader) (Note, error) {
+	cueID, text, err := decodeText(r)
+	return Note{CueID: cueID, Text: text}, err
+}
+
+// encodeText writes a labl/note-shaped sub-chunk: a 4-byte cue ID followed
+// by text, a NUL terminator, and (if that makes the payload odd) a single
+// pad byte that keeps the chunk WORD-aligned without being counted in the
+// chunk's own size.
+func encodeText(w io.Writer, chunkID [4]byte, cueID int32, text string) (int64, error) {
+	payload := append([]byte(text), 0)
+	size := int32(4 + len(payload))
+
+	if err := binary.Write(w, binary.LittleEndian, chunkID); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, size); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, cueID); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return 0, err
+	}
+
+	written := int64(8 + size)
+	if size%2 != 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return 0, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+func decodeText(r io.Reader) (int32, string, error) {
+	var cueID int32
+	if err := binary.Read(r, binary.LittleEndian, &cueID); err != nil {
+		return 0, "", err
+	}
+
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return 0, "", err
+	}
+	return cueID, string(bytes.TrimRight(raw, "\x00")), nil
+}
+
+// LabeledText is an adtl "ltxt" sub-chunk: the sample range a cue point
+// marks, plus a four-character purpose code (e.g. "mark").
+type LabeledText struct {
+	CueID        int32
+	SampleLength uint32
+	Purpose      [4]byte
+	Country      int16
+	Language     int16
+	Dialect      int16
+	CodePage     int16
+}
+
+func (l LabeledText) Encode(w io.Writer) (int64, error) {
+	size := int32(binary.Size(l))
+	if err := binary.Write(w, binary.LittleEndian, [4]byte{'l', 't', 'x', 't'}); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, size); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, &l); err != nil {
+		return 0, err
+	}
+	return int64(8 + size), nil
+}
+
+// DecodeLabeledText reads a LabeledText body, as yielded by a ChunkReader
+// for an "ltxt" sub-chunk.
+func DecodeLabeledText(r io.Reader) (LabeledText, error) {
+	var l LabeledText
+	if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+		return LabeledText{}, err
+	}
+	return l, nil
+}
+
+// AssocDataList is the RIFF "LIST" chunk with form type "adtl": the
+// associated data list holding the Labels, Notes, and LabeledTexts for the
+// cue points in a "cue " chunk.
+type AssocDataList struct {
+	Labels       []Label
+	Notes        []Note
+	LabeledTexts []LabeledText
+}
+
+// DecodeAssocDataList reads an AssocDataList body of the given declared
+// size, as yielded by a ChunkReader for a "LIST" chunk whose form type is
+// "adtl".
+func DecodeAssocDataList(r io.Reader, size int64) (AssocDataList, error) {
+	var form [4]byte
+	if err := binary.Read(r, binary.LittleEndian, &form); err != nil {
+		return AssocDataList{}, err
+	}
+	if string(form[:]) != "adtl" {
+		return AssocDataList{}, fmt.Errorf("not an adtl LIST: form type %q", form[:])
+	}
+
+	var list AssocDataList
+	chunks := NewChunkReader(io.LimitReader(r, size-4))
+	for {
+		id, _, section, err := chunks.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return AssocDataList{}, err
+		}
+
+		switch id {
+		case "labl":
+			l, err := DecodeLabel(section)
+			if err != nil {
+				return AssocDataList{}, err
+			}
+			list.Labels = append(list.Labels, l)
+		case "note":
+			n, err := DecodeNote(section)
+			if err != nil {
+				return AssocDataList{}, err
+			}
+			list.Notes = append(list.Notes, n)
+		case "ltxt":
+			l, err := DecodeLabeledText(section)
+			if err != nil {
+				return AssocDataList{}, err
+			}
+			list.LabeledTexts = append(list.LabeledTexts, l)
+		}
+	}
+	return list, nil
+}
+
+// WriteTo writes the full "LIST" chunk, including its ID, size header, and
+// "adtl" form type, to w.
+func (l AssocDataList) WriteTo(w io.Writer) (int64, error) {
+	var body bytes.Buffer
+	for _, label := range l.Labels {
+		if _, err := label.Encode(&body); err != nil {
+			return 0, err
+		}
+	}
+	for _, note := range l.Notes {
+		if _, err := note.Encode(&body); err != nil {
+			return 0, err
+		}
+	}
+	for _, ltxt := range l.LabeledTexts {
+		if _, err := ltxt.Encode(&body); err != nil {
+			return 0, err
+		}
+	}
+
+	size := int32(4 + body.Len())
+	if err := binary.Write(w, binary.LittleEndian, [4]byte{'L', 'I', 'S', 'T'}); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, size); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, [4]byte{'a', 'd', 't', 'l'}); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return 0, err
+	}
+	return int64(8 + size), nil
+}
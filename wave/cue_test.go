@@ -0,0 +1,56 @@
+package wave
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestCueChunkRoundTrip(t *testing.T) {
+	in := CueChunk{CuePoints: []CuePoint{
+		{Identifier: 1, Position: 100, ChunkID: [4]byte{'d', 'a', 't', 'a'}, SampleOffset: 100},
+		{Identifier: 2, Position: 200, ChunkID: [4]byte{'d', 'a', 't', 'a'}, SampleOffset: 200},
+	}}
+
+	var buf bytes.Buffer
+	if _, err := in.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	out, err := DecodeCueChunk(bytes.NewReader(buf.Bytes()[8:]))
+	if err != nil {
+		t.Fatalf("DecodeCueChunk: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", out, in)
+	}
+}
+
+func TestAssocDataListRoundTrip(t *testing.T) {
+	in := AssocDataList{
+		Labels:       []Label{{CueID: 1, Text: "Intro"}},
+		Notes:        []Note{{CueID: 1, Text: "Range"}},
+		LabeledTexts: []LabeledText{{CueID: 1, SampleLength: 50, Purpose: [4]byte{'m', 'a', 'r', 'k'}, Country: 1}},
+	}
+
+	var buf bytes.Buffer
+	if _, err := in.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	body := buf.Bytes()[8:] // strip the LIST chunk's ID+size header
+	out, err := DecodeAssocDataList(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("DecodeAssocDataList: %v", err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", out, in)
+	}
+}
+
+func TestDecodeAssocDataListRejectsNonAdtl(t *testing.T) {
+	body := append([]byte("INFO"), 0, 0, 0, 0)
+	if _, err := DecodeAssocDataList(bytes.NewReader(body), int64(len(body))); err == nil {
+		t.Fatal("DecodeAssocDataList on a non-adtl LIST = nil error, want error")
+	}
+}
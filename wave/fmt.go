@@ -0,0 +1,148 @@
+package wave
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	formatPCM        = 1
+	formatIEEEFloat  = 3
+	formatExtensible = 0xFFFE
+)
+
+// subFormat GUIDs carried in a WAVE_FORMAT_EXTENSIBLE fmt chunk; see the
+// Microsoft Multimedia GUID registry. Both share the same trailing bytes
+// and differ only in the first field, which mirrors the ordinary
+// AudioFormat codes above.
+var (
+	subFormatPCM       = [16]byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+	subFormatIEEEFloat = [16]byte{0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+)
+
+// FmtExtension holds the fields appended to a WAVE_FORMAT_EXTENSIBLE fmt
+// chunk, after the 16-byte PCM-shaped core and the cbSize field.
+type FmtExtension struct {
+	ValidBitsPerSample uint16
+	ChannelMask        uint32
+	SubFormat          [16]byte
+}
+
+// FmtChunk describes the audio format of the "fmt " chunk: sample rate,
+// channel count, bit depth, and so on. Extension is populated for
+// WAVE_FORMAT_EXTENSIBLE; Raw always holds the exact bytes of the chunk
+// body, so WriteTo reproduces the input's format header byte-for-byte.
+type FmtChunk struct {
+	AudioFormat   uint16
+	NumChannels   int16
+	SampleRate    int32
+	ByteRate      int32
+	BlockAlign    int16
+	BitsPerSample int16
+
+	Extension *FmtExtension
+	Raw       []byte
+}
+
+// DecodeFmtChunk reads a FmtChunk body of the given declared size, as
+// yielded by a ChunkReader for a "fmt " chunk. size may be larger than the
+// 16-byte PCM core to carry a cbSize field and, for
+// WAVE_FORMAT_EXTENSIBLE, its 22-byte extension.
+func DecodeFmtChunk(r io.Reader, size int32) (FmtChunk, error) {
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		return FmtChunk{}, err
+	}
+
+	var core struct {
+		AudioFormat   uint16
+		NumChannels   int16
+		SampleRate    int32
+		ByteRate      int32
+		BlockAlign    int16
+		BitsPerSample int16
+	}
+	if err := binary.Read(bytes.NewReader(raw), binary.LittleEndian, &core); err != nil {
+		return FmtChunk{}, err
+	}
+
+	c := FmtChunk{
+		AudioFormat:   core.AudioFormat,
+		NumChannels:   core.NumChannels,
+		SampleRate:    core.SampleRate,
+		ByteRate:      core.ByteRate,
+		BlockAlign:    core.BlockAlign,
+		BitsPerSample: core.BitsPerSample,
+		Raw:           raw,
+	}
+
+	switch c.AudioFormat {
+	case formatExtensible:
+		const extOffset = 16 + 2 // core + cbSize
+		if len(raw) < extOffset+22 {
+			return FmtChunk{}, fmt.Errorf("fmt chunk too short for WAVE_FORMAT_EXTENSIBLE: %d bytes", len(raw))
+		}
+
+		var ext FmtExtension
+		if err := binary.Read(bytes.NewReader(raw[extOffset:]), binary.LittleEndian, &ext); err != nil {
+			return FmtChunk{}, err
+		}
+		if ext.SubFormat != subFormatPCM && ext.SubFormat != subFormatIEEEFloat {
+			return FmtChunk{}, fmt.Errorf("unsupported WAVE_FORMAT_EXTENSIBLE sub-format: % x", ext.SubFormat)
+		}
+		c.Extension = &ext
+	case formatPCM, formatIEEEFloat:
+		// no extension to parse
+	default:
+		return FmtChunk{}, fmt.Errorf("unsupported audio format: %d", c.AudioFormat)
+	}
+
+	return c, nil
+}
+
+// NewPCMFmtChunk builds a plain 16-byte PCM fmt chunk, as used when
+// synthesizing a fresh WAV file.
+func NewPCMFmtChunk(channels, sampleRate, bitsPerSample int) FmtChunk {
+	blockAlign := int16(channels * bitsPerSample / 8)
+
+	c := FmtChunk{
+		AudioFormat:   formatPCM,
+		NumChannels:   int16(channels),
+		SampleRate:    int32(sampleRate),
+		ByteRate:      int32(blockAlign) * int32(sampleRate),
+		BlockAlign:    blockAlign,
+		BitsPerSample: int16(bitsPerSample),
+	}
+
+	var raw bytes.Buffer
+	binary.Write(&raw, binary.LittleEndian, &struct {
+		AudioFormat   uint16
+		NumChannels   int16
+		SampleRate    int32
+		ByteRate      int32
+		BlockAlign    int16
+		BitsPerSample int16
+	}{c.AudioFormat, c.NumChannels, c.SampleRate, c.ByteRate, c.BlockAlign, c.BitsPerSample})
+	c.Raw = raw.Bytes()
+
+	return c
+}
+
+// WriteTo writes the full "fmt " chunk, including its ID and size header,
+// to w. The body is c.Raw verbatim, so any extension present on decode is
+// preserved on write.
+func (c FmtChunk) WriteTo(w io.Writer) (int64, error) {
+	if err := binary.Write(w, binary.LittleEndian, [4]byte{'f', 'm', 't', ' '}); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(len(c.Raw))); err != nil {
+		return 0, err
+	}
+	n, err := w.Write(c.Raw)
+	if err != nil {
+		return 0, err
+	}
+	return int64(8 + n), nil
+}
@@ -0,0 +1,91 @@
+package wave
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecodeFmtChunkPCM(t *testing.T) {
+	c := NewPCMFmtChunk(2, 44100, 16)
+
+	var buf bytes.Buffer
+	if _, err := c.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	out, err := DecodeFmtChunk(bytes.NewReader(buf.Bytes()[8:]), int32(len(c.Raw)))
+	if err != nil {
+		t.Fatalf("DecodeFmtChunk: %v", err)
+	}
+	if out.AudioFormat != formatPCM || out.NumChannels != 2 || out.SampleRate != 44100 || out.BitsPerSample != 16 {
+		t.Errorf("DecodeFmtChunk = %+v, want PCM 2ch/44100/16", out)
+	}
+	if out.Extension != nil {
+		t.Errorf("DecodeFmtChunk.Extension = %+v, want nil for plain PCM", out.Extension)
+	}
+	if !bytes.Equal(out.Raw, c.Raw) {
+		t.Errorf("DecodeFmtChunk.Raw = % x, want % x", out.Raw, c.Raw)
+	}
+}
+
+func TestDecodeFmtChunkIEEEFloat(t *testing.T) {
+	raw := []byte{
+		0x03, 0x00, // AudioFormat = IEEE float
+		0x01, 0x00, // NumChannels = 1
+		0x44, 0xAC, 0x00, 0x00, // SampleRate = 44100
+		0x10, 0xB1, 0x02, 0x00, // ByteRate
+		0x04, 0x00, // BlockAlign
+		0x20, 0x00, // BitsPerSample = 32
+	}
+
+	out, err := DecodeFmtChunk(bytes.NewReader(raw), int32(len(raw)))
+	if err != nil {
+		t.Fatalf("DecodeFmtChunk: %v", err)
+	}
+	if out.AudioFormat != formatIEEEFloat || out.BitsPerSample != 32 {
+		t.Errorf("DecodeFmtChunk = %+v, want IEEE float 32-bit", out)
+	}
+	if out.Extension != nil {
+		t.Errorf("DecodeFmtChunk.Extension = %+v, want nil for IEEE float", out.Extension)
+	}
+}
+
+func TestDecodeFmtChunkExtensible(t *testing.T) {
+	raw := make([]byte, 16+2+22)
+	le := func(off int, v uint32, n int) {
+		for i := 0; i < n; i++ {
+			raw[off+i] = byte(v >> (8 * i))
+		}
+	}
+	le(0, formatExtensible, 2)
+	le(2, 2, 2)     // NumChannels
+	le(4, 44100, 4) // SampleRate
+	le(8, 0, 4)     // ByteRate
+	le(12, 0, 2)    // BlockAlign
+	le(14, 24, 2)   // BitsPerSample
+	le(16, 22, 2)   // cbSize
+	le(18, 24, 2)   // ValidBitsPerSample
+	le(20, 0, 4)    // ChannelMask
+	copy(raw[24:], subFormatPCM[:])
+
+	out, err := DecodeFmtChunk(bytes.NewReader(raw), int32(len(raw)))
+	if err != nil {
+		t.Fatalf("DecodeFmtChunk: %v", err)
+	}
+	if out.AudioFormat != formatExtensible {
+		t.Errorf("DecodeFmtChunk.AudioFormat = %d, want %d", out.AudioFormat, formatExtensible)
+	}
+	if out.Extension == nil {
+		t.Fatal("DecodeFmtChunk.Extension = nil, want populated for WAVE_FORMAT_EXTENSIBLE")
+	}
+	if out.Extension.SubFormat != subFormatPCM {
+		t.Errorf("DecodeFmtChunk.Extension.SubFormat = % x, want % x", out.Extension.SubFormat, subFormatPCM)
+	}
+}
+
+func TestDecodeFmtChunkUnsupportedFormat(t *testing.T) {
+	raw := []byte{0x99, 0x00, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 16, 0}
+	if _, err := DecodeFmtChunk(bytes.NewReader(raw), int32(len(raw))); err == nil {
+		t.Fatal("DecodeFmtChunk with unsupported AudioFormat = nil error, want error")
+	}
+}
@@ -0,0 +1,86 @@
+package wave
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// FrameGenerator returns the signed sample value for the given channel at
+// the given (zero-based) frame index.
+type FrameGenerator func(frame int64, channel int) int32
+
+// FrameReader is an io.Reader that synthesizes PCM frames on demand via a
+// FrameGenerator instead of materializing the whole buffer up front —
+// important for long generated ambience beds.
+type FrameReader struct {
+	gen            FrameGenerator
+	channels       int
+	bytesPerSample int
+	frame          int64
+	totalFrames    int64
+}
+
+// NewFrameReader returns a FrameReader that synthesizes totalFrames frames
+// of channels-channel, bitsPerSample-deep PCM audio using gen.
+func NewFrameReader(totalFrames int64, channels, bitsPerSample int, gen FrameGenerator) *FrameReader {
+	return &FrameReader{
+		gen:            gen,
+		channels:       channels,
+		bytesPerSample: bitsPerSample / 8,
+		totalFrames:    totalFrames,
+	}
+}
+
+func (r *FrameReader) Read(p []byte) (int, error) {
+	frameSize := r.channels * r.bytesPerSample
+
+	n := 0
+	for len(p)-n >= frameSize && r.frame < r.totalFrames {
+		for ch := 0; ch < r.channels; ch++ {
+			putSample(p[n:], r.gen(r.frame, ch), r.bytesPerSample)
+			n += r.bytesPerSample
+		}
+		r.frame++
+	}
+
+	if r.frame >= r.totalFrames {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// putSample packs a signed sample into bytesPerSample little-endian bytes.
+// 8-bit PCM is the one exception in the WAVE spec: it's unsigned, centered
+// on 128.
+func putSample(buf []byte, sample int32, bytesPerSample int) {
+	switch bytesPerSample {
+	case 1:
+		buf[0] = byte(sample + 128)
+	case 2:
+		binary.LittleEndian.PutUint16(buf, uint16(int16(sample)))
+	case 3:
+		buf[0] = byte(sample)
+		buf[1] = byte(sample >> 8)
+		buf[2] = byte(sample >> 16)
+	case 4:
+		binary.LittleEndian.PutUint32(buf, uint32(sample))
+	}
+}
+
+// SilenceGenerator is a FrameGenerator that produces digital silence.
+func SilenceGenerator() FrameGenerator {
+	return func(frame int64, channel int) int32 {
+		return 0
+	}
+}
+
+// SineGenerator is a FrameGenerator that produces a full-scale sine wave
+// at freqHz, suitable for bitsPerSample-deep PCM.
+func SineGenerator(sampleRate int32, freqHz float64, bitsPerSample int) FrameGenerator {
+	amplitude := float64(int64(1)<<(uint(bitsPerSample)-1) - 1)
+	return func(frame int64, channel int) int32 {
+		phase := 2 * math.Pi * freqHz * float64(frame) / float64(sampleRate)
+		return int32(amplitude * math.Sin(phase))
+	}
+}
@@ -0,0 +1,112 @@
+package wave
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// SampleLoop is one loop record within an "smpl" chunk. Type 0 means
+// forward playback, and PlayCount 0 means loop infinitely.
+type SampleLoop struct {
+	CuePointID int32
+	Type       int32
+	Start      uint32
+	End        uint32
+	Fraction   uint32
+	PlayCount  uint32
+}
+
+// SamplerChunk is the RIFF "smpl" chunk. Players that honor sample loops
+// (rather than cue points) use this to loop playback.
+type SamplerChunk struct {
+	Manufacturer      uint32
+	Product           uint32
+	SamplePeriod      uint32
+	MIDIUnityNote     uint32
+	MIDIPitchFraction uint32
+	SMPTEFormat       uint32
+	SMPTEOffset       uint32
+	SamplerData       uint32
+	Loops             []SampleLoop
+}
+
+// DecodeSamplerChunk reads a SamplerChunk body, as yielded by a ChunkReader
+// for an "smpl" chunk.
+func DecodeSamplerChunk(r io.Reader) (SamplerChunk, error) {
+	var fixed struct {
+		Manufacturer      uint32
+		Product           uint32
+		SamplePeriod      uint32
+		MIDIUnityNote     uint32
+		MIDIPitchFraction uint32
+		SMPTEFormat       uint32
+		SMPTEOffset       uint32
+		NumSampleLoops    uint32
+		SamplerData       uint32
+	}
+	if err := binary.Read(r, binary.LittleEndian, &fixed); err != nil {
+		return SamplerChunk{}, err
+	}
+
+	loops := make([]SampleLoop, fixed.NumSampleLoops)
+	for i := range loops {
+		if err := binary.Read(r, binary.LittleEndian, &loops[i]); err != nil {
+			return SamplerChunk{}, err
+		}
+	}
+
+	return SamplerChunk{
+		Manufacturer:      fixed.Manufacturer,
+		Product:           fixed.Product,
+		SamplePeriod:      fixed.SamplePeriod,
+		MIDIUnityNote:     fixed.MIDIUnityNote,
+		MIDIPitchFraction: fixed.MIDIPitchFraction,
+		SMPTEFormat:       fixed.SMPTEFormat,
+		SMPTEOffset:       fixed.SMPTEOffset,
+		SamplerData:       fixed.SamplerData,
+		Loops:             loops,
+	}, nil
+}
+
+// WriteTo writes the full "smpl" chunk, including its ID and size header,
+// to w. NumSampleLoops is derived from len(c.Loops).
+func (c SamplerChunk) WriteTo(w io.Writer) (int64, error) {
+	fixed := struct {
+		Manufacturer      uint32
+		Product           uint32
+		SamplePeriod      uint32
+		MIDIUnityNote     uint32
+		MIDIPitchFraction uint32
+		SMPTEFormat       uint32
+		SMPTEOffset       uint32
+		NumSampleLoops    uint32
+		SamplerData       uint32
+	}{
+		Manufacturer:      c.Manufacturer,
+		Product:           c.Product,
+		SamplePeriod:      c.SamplePeriod,
+		MIDIUnityNote:     c.MIDIUnityNote,
+		MIDIPitchFraction: c.MIDIPitchFraction,
+		SMPTEFormat:       c.SMPTEFormat,
+		SMPTEOffset:       c.SMPTEOffset,
+		NumSampleLoops:    uint32(len(c.Loops)),
+		SamplerData:       c.SamplerData,
+	}
+	size := int32(binary.Size(fixed) + len(c.Loops)*binary.Size(SampleLoop{}))
+
+	if err := binary.Write(w, binary.LittleEndian, [4]byte{'s', 'm', 'p', 'l'}); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, size); err != nil {
+		return 0, err
+	}
+	if err := binary.Write(w, binary.LittleEndian, &fixed); err != nil {
+		return 0, err
+	}
+	for _, loop := range c.Loops {
+		if err := binary.Write(w, binary.LittleEndian, loop); err != nil {
+			return 0, err
+		}
+	}
+	return int64(8 + size), nil
+}
@@ -0,0 +1,34 @@
+package wave
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestSamplerChunkRoundTrip(t *testing.T) {
+	in := SamplerChunk{
+		Manufacturer:  1,
+		SamplePeriod:  22675,
+		MIDIUnityNote: 60,
+		Loops: []SampleLoop{
+			{CuePointID: 1, Start: 100, End: 4410},
+		},
+	}
+
+	var buf bytes.Buffer
+	if _, err := in.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	// Skip the chunk ID and size header DecodeSamplerChunk expects the
+	// caller (a ChunkReader) to have already consumed.
+	out, err := DecodeSamplerChunk(bytes.NewReader(buf.Bytes()[8:]))
+	if err != nil {
+		t.Fatalf("DecodeSamplerChunk: %v", err)
+	}
+
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip mismatch:\n got %+v\nwant %+v", out, in)
+	}
+}
@@ -0,0 +1,53 @@
+// Package wave provides a minimal reader/writer for RIFF/WAVE audio files,
+// including the cue point and associated data list ("adtl") chunks used to
+// mark named regions such as loop points.
+package wave
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Header is the 12-byte RIFF/WAVE container header that precedes every
+// chunk in a WAVE file. ChunkID and Format are implied to be "RIFF" and
+// "WAVE" respectively and are not stored.
+type Header struct {
+	ChunkSize int32
+}
+
+// ReadHeader reads and validates the RIFF/WAVE header from r.
+func ReadHeader(r io.Reader) (Header, error) {
+	var raw struct {
+		ChunkID   [4]byte
+		ChunkSize int32
+		Format    [4]byte
+	}
+	if err := binary.Read(r, binary.LittleEndian, &raw); err != nil {
+		return Header{}, err
+	}
+	if string(raw.ChunkID[:]) != "RIFF" {
+		return Header{}, fmt.Errorf("not a RIFF file: chunk ID %q", raw.ChunkID[:])
+	}
+	if string(raw.Format[:]) != "WAVE" {
+		return Header{}, fmt.Errorf("not a WAVE file: format %q", raw.Format[:])
+	}
+	return Header{ChunkSize: raw.ChunkSize}, nil
+}
+
+// WriteTo writes the 12-byte header to w.
+func (h Header) WriteTo(w io.Writer) (int64, error) {
+	raw := struct {
+		ChunkID   [4]byte
+		ChunkSize int32
+		Format    [4]byte
+	}{
+		ChunkID:   [4]byte{'R', 'I', 'F', 'F'},
+		ChunkSize: h.ChunkSize,
+		Format:    [4]byte{'W', 'A', 'V', 'E'},
+	}
+	if err := binary.Write(w, binary.LittleEndian, &raw); err != nil {
+		return 0, err
+	}
+	return int64(binary.Size(raw)), nil
+}